@@ -0,0 +1,141 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"math"
+
+	metricpb "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+
+	"go.opentelemetry.io/otel/api/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregator"
+)
+
+// exponentialHistogram transforms an ExponentialHistogram Aggregator into
+// an OTLP Metric carrying a SummaryDataPoint whose PercentileValues are
+// quantiles interpolated from the histogram's buckets, at the quantiles
+// cfg configures (or SummaryConfig's defaults, if cfg is nil). This is a
+// stopgap: OTLP has no native sparse-histogram message yet, so the full
+// bucket layout cannot be exported losslessly.
+func exponentialHistogram(desc *metric.Descriptor, labels export.Labels, a aggregator.ExponentialHistogram, cfg *SummaryConfig) (*metricpb.Metric, error) {
+	sum, err := a.Sum()
+	if err != nil {
+		return nil, err
+	}
+	count, err := a.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	quantiles := cfg.quantiles()
+	percentiles := make([]*metricpb.SummaryDataPoint_ValueAtPercentile, 0, len(quantiles))
+	for _, q := range quantiles {
+		v, err := exponentialQuantile(a, count, q)
+		if err != nil {
+			return nil, err
+		}
+		percentiles = append(percentiles, &metricpb.SummaryDataPoint_ValueAtPercentile{
+			Percentile: q * 100,
+			Value:      v,
+		})
+	}
+
+	return &metricpb.Metric{
+		MetricDescriptor: &metricpb.MetricDescriptor{
+			Name:        desc.Name(),
+			Description: desc.Description(),
+			Unit:        string(desc.Unit()),
+			Type:        metricpb.MetricDescriptor_SUMMARY,
+			Labels:      stringKeyValues(labels.Iter()),
+		},
+		SummaryDataPoints: []*metricpb.SummaryDataPoint{
+			{
+				Sum:              sum.CoerceToFloat64(desc.NumberKind()),
+				Count:            uint64(count),
+				PercentileValues: percentiles,
+			},
+		},
+	}, nil
+}
+
+// exponentialQuantile estimates the value at quantile q (in [0, 1]) by
+// walking the histogram's buckets, from the most negative to the most
+// positive, until the target rank is reached, then linearly interpolating
+// across the bucket's [lower, upper) boundary.
+func exponentialQuantile(a aggregator.ExponentialHistogram, count int64, q float64) (float64, error) {
+	if count == 0 {
+		return 0, aggregator.ErrNoData
+	}
+
+	scale := a.Scale()
+	negOffset, negCounts := a.Negative()
+	posOffset, posCounts := a.Positive()
+	zero := a.ZeroCount()
+
+	target := q * float64(count-1)
+	var cumulative float64
+
+	for i := len(negCounts) - 1; i >= 0; i-- {
+		c := float64(negCounts[i])
+		if c == 0 {
+			continue
+		}
+		if cumulative+c > target {
+			frac := (target - cumulative) / c
+			idx := negOffset + i
+			lo, hi := -bucketUpper(idx, scale), -bucketLower(idx, scale)
+			return lo + frac*(hi-lo), nil
+		}
+		cumulative += c
+	}
+
+	if zero > 0 {
+		if cumulative+float64(zero) > target {
+			return 0, nil
+		}
+		cumulative += float64(zero)
+	}
+
+	for i, c := range posCounts {
+		cf := float64(c)
+		if cf == 0 {
+			continue
+		}
+		if cumulative+cf > target {
+			frac := (target - cumulative) / cf
+			idx := posOffset + i
+			lo, hi := bucketLower(idx, scale), bucketUpper(idx, scale)
+			return lo + frac*(hi-lo), nil
+		}
+		cumulative += cf
+	}
+
+	if len(posCounts) > 0 {
+		return bucketUpper(posOffset+len(posCounts)-1, scale), nil
+	}
+	return 0, nil
+}
+
+// bucketLower and bucketUpper return the [lower, upper) boundary of the
+// bucket at idx, for a histogram at the given scale: bucket idx covers
+// values v with floor(log2(v) * 2**scale) == idx.
+func bucketLower(idx int, scale int8) float64 {
+	return math.Exp2(float64(idx) * math.Ldexp(1, -int(scale)))
+}
+
+func bucketUpper(idx int, scale int8) float64 {
+	return bucketLower(idx+1, scale)
+}
@@ -0,0 +1,294 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	commonpb "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	metricpb "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregator"
+)
+
+var (
+	// ErrUnimplementedAgg is returned when a transformation of an unimplemented
+	// Aggregator is attempted.
+	ErrUnimplementedAgg = errors.New("unimplemented Aggregator")
+
+	// ErrUnknownValueType is returned when a transformation of an unknown
+	// core.NumberKind is attempted.
+	ErrUnknownValueType = errors.New("invalid core.NumberKind")
+)
+
+// Record transforms a Record, consisting of a Descriptor, a LabelSet, and an
+// Aggregator, into an OTLP Metric. An error is returned if the Aggregator
+// implements no known aggregation interface.
+//
+// If tracker is non-nil, the returned Metric's Int64, Double, and Summary
+// data points have their StartTimeUnixNano set from the series'
+// first-observed (or process-start, depending on tracker's mode)
+// timestamp, with counter resets detected and reflected in that start
+// time; Histogram data points are left without a start time. now is the
+// time the Aggregator was checkpointed.
+//
+// cfg configures the quantiles reported for Aggregators that implement
+// aggregator.Distribution or aggregator.ExponentialHistogram. A nil cfg
+// reports SummaryConfig's default quantiles.
+func Record(tracker *StartTimeTracker, cfg *SummaryConfig, r export.Record, now time.Time) (*metricpb.Metric, error) {
+	desc := r.Descriptor()
+	labels := r.Labels()
+	agg := r.Aggregator()
+
+	var (
+		m   *metricpb.Metric
+		err error
+	)
+	switch a := agg.(type) {
+	case aggregator.ExponentialHistogram:
+		m, err = exponentialHistogram(desc, labels, a, cfg)
+	case aggregator.Histogram:
+		m, err = histogram(desc, labels, a)
+	case aggregator.Distribution:
+		m, err = distribution(desc, labels, a, cfg)
+	case aggregator.MinMaxSumCount:
+		m, err = minMaxSumCount(desc, labels, a)
+	case aggregator.Sum:
+		m, err = sum(desc, labels, a)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnimplementedAgg, agg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tracker != nil {
+		tracker.Record(desc, labels, m, now)
+	}
+	return m, nil
+}
+
+// minMaxSumCount transforms a MinMaxSumCount Aggregator into an OTLP Metric
+// with one SummaryDataPoint.
+func minMaxSumCount(desc *metric.Descriptor, labels export.Labels, a aggregator.MinMaxSumCount) (*metricpb.Metric, error) {
+	min, max, sum, count, err := minMaxSumCountValues(a)
+	if err != nil {
+		return nil, err
+	}
+
+	numberKind := desc.NumberKind()
+	return &metricpb.Metric{
+		MetricDescriptor: &metricpb.MetricDescriptor{
+			Name:        desc.Name(),
+			Description: desc.Description(),
+			Unit:        string(desc.Unit()),
+			Type:        metricpb.MetricDescriptor_SUMMARY,
+			Labels:      stringKeyValues(labels.Iter()),
+		},
+		SummaryDataPoints: []*metricpb.SummaryDataPoint{
+			{
+				Count: uint64(count),
+				Sum:   sum.CoerceToFloat64(numberKind),
+				PercentileValues: []*metricpb.SummaryDataPoint_ValueAtPercentile{
+					{
+						Percentile: 0.0,
+						Value:      min.CoerceToFloat64(numberKind),
+					},
+					{
+						Percentile: 100.0,
+						Value:      max.CoerceToFloat64(numberKind),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// minMaxSumCountValues returns the minimum, maximum, sum, and count values of
+// a MinMaxSumCount Aggregator. An ErrNoData error is returned if the
+// Aggregator has not been checkpointed yet.
+func minMaxSumCountValues(a aggregator.MinMaxSumCount) (min, max, sum core.Number, count int64, err error) {
+	if min, err = a.Min(); err != nil {
+		return
+	}
+	if max, err = a.Max(); err != nil {
+		return
+	}
+	if sum, err = a.Sum(); err != nil {
+		return
+	}
+	if count, err = a.Count(); err != nil {
+		return
+	}
+	return
+}
+
+// distribution transforms a Distribution Aggregator into an OTLP Metric
+// with one SummaryDataPoint, reporting the quantiles configured by cfg
+// (or SummaryConfig's defaults, if cfg is nil) rather than the fixed
+// 0th/100th percentile pair used for a plain MinMaxSumCount.
+func distribution(desc *metric.Descriptor, labels export.Labels, a aggregator.Distribution, cfg *SummaryConfig) (*metricpb.Metric, error) {
+	sum, err := a.Sum()
+	if err != nil {
+		return nil, err
+	}
+	count, err := a.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	numberKind := desc.NumberKind()
+	quantiles := cfg.quantiles()
+	percentileValues := make([]*metricpb.SummaryDataPoint_ValueAtPercentile, 0, len(quantiles))
+	for _, q := range quantiles {
+		v, err := a.Quantile(q)
+		if err != nil {
+			return nil, err
+		}
+		percentileValues = append(percentileValues, &metricpb.SummaryDataPoint_ValueAtPercentile{
+			Percentile: q * 100,
+			Value:      v.CoerceToFloat64(numberKind),
+		})
+	}
+
+	return &metricpb.Metric{
+		MetricDescriptor: &metricpb.MetricDescriptor{
+			Name:        desc.Name(),
+			Description: desc.Description(),
+			Unit:        string(desc.Unit()),
+			Type:        metricpb.MetricDescriptor_SUMMARY,
+			Labels:      stringKeyValues(labels.Iter()),
+		},
+		SummaryDataPoints: []*metricpb.SummaryDataPoint{
+			{
+				Count:            uint64(count),
+				Sum:              sum.CoerceToFloat64(numberKind),
+				PercentileValues: percentileValues,
+			},
+		},
+	}, nil
+}
+
+// sum transforms a Sum Aggregator into an OTLP Metric with either one
+// Int64DataPoint or one DoubleDataPoint, depending on the Descriptor's
+// core.NumberKind.
+func sum(desc *metric.Descriptor, labels export.Labels, a aggregator.Sum) (*metricpb.Metric, error) {
+	sum, err := a.Sum()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &metricpb.Metric{
+		MetricDescriptor: &metricpb.MetricDescriptor{
+			Name:        desc.Name(),
+			Description: desc.Description(),
+			Unit:        string(desc.Unit()),
+			Labels:      stringKeyValues(labels.Iter()),
+		},
+	}
+
+	switch n := desc.NumberKind(); n {
+	case core.Int64NumberKind:
+		m.MetricDescriptor.Type = metricpb.MetricDescriptor_COUNTER_INT64
+		m.Int64DataPoints = []*metricpb.Int64DataPoint{
+			{Value: sum.CoerceToInt64(n)},
+		}
+	case core.Float64NumberKind:
+		m.MetricDescriptor.Type = metricpb.MetricDescriptor_COUNTER_DOUBLE
+		m.DoubleDataPoints = []*metricpb.DoubleDataPoint{
+			{Value: sum.CoerceToFloat64(n)},
+		}
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownValueType, n)
+	}
+
+	return m, nil
+}
+
+// histogram transforms a Histogram Aggregator into an OTLP Metric with
+// either one int64 or one double HistogramDataPoint, depending on the
+// Descriptor's core.NumberKind. The MetricDescriptor.Type is set
+// accordingly.
+func histogram(desc *metric.Descriptor, labels export.Labels, a aggregator.Histogram) (*metricpb.Metric, error) {
+	boundaries, counts, err := a.Histogram()
+	if err != nil {
+		return nil, err
+	}
+	sum, err := a.Sum()
+	if err != nil {
+		return nil, err
+	}
+	count, err := a.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	numberKind := desc.NumberKind()
+	explicitBounds := make([]float64, len(boundaries))
+	for i, b := range boundaries {
+		explicitBounds[i] = b.CoerceToFloat64(numberKind)
+	}
+
+	m := &metricpb.Metric{
+		MetricDescriptor: &metricpb.MetricDescriptor{
+			Name:        desc.Name(),
+			Description: desc.Description(),
+			Unit:        string(desc.Unit()),
+			Labels:      stringKeyValues(labels.Iter()),
+		},
+		HistogramDataPoints: []*metricpb.HistogramDataPoint{
+			{
+				Sum:            sum.CoerceToFloat64(numberKind),
+				Count:          uint64(count),
+				ExplicitBounds: explicitBounds,
+				BucketCounts:   counts,
+			},
+		},
+	}
+
+	switch numberKind {
+	case core.Int64NumberKind:
+		m.MetricDescriptor.Type = metricpb.MetricDescriptor_HISTOGRAM_INT64
+	case core.Float64NumberKind:
+		m.MetricDescriptor.Type = metricpb.MetricDescriptor_HISTOGRAM_DOUBLE
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownValueType, numberKind)
+	}
+
+	return m, nil
+}
+
+// stringKeyValues transforms an export.LabelIterator into OTLP
+// StringKeyValues.
+func stringKeyValues(iter export.LabelIterator) []*commonpb.StringKeyValue {
+	l := iter.Len()
+	if l == 0 {
+		return nil
+	}
+	result := make([]*commonpb.StringKeyValue, 0, l)
+	for iter.Next() {
+		kv := iter.Label()
+		result = append(result, &commonpb.StringKeyValue{
+			Key:   string(kv.Key),
+			Value: kv.Value.Emit(),
+		})
+	}
+	return result
+}
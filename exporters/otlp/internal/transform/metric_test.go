@@ -29,6 +29,8 @@ import (
 	"go.opentelemetry.io/otel/api/unit"
 	export "go.opentelemetry.io/otel/sdk/export/metric"
 	"go.opentelemetry.io/otel/sdk/export/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/ddsketch"
+	histogramAgg "go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/minmaxsumcount"
 	sumAgg "go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
 )
@@ -285,6 +287,55 @@ func TestSumFloat64DataPoints(t *testing.T) {
 	}
 }
 
+func TestHistogramInt64DataPoints(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Int64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	h := histogramAgg.New([]core.Number{core.NewInt64Number(10)})
+	assert.NoError(t, h.Update(context.Background(), core.NewInt64Number(1), &desc))
+	assert.NoError(t, h.Update(context.Background(), core.NewInt64Number(10), &desc))
+	h.Checkpoint(context.Background(), &desc)
+
+	expected := []*metricpb.HistogramDataPoint{
+		{
+			Sum:            11,
+			Count:          2,
+			ExplicitBounds: []float64{10},
+			BucketCounts:   []uint64{1, 1},
+		},
+	}
+	m, err := histogram(&desc, labels, h)
+	if assert.NoError(t, err) {
+		assert.Equal(t, metricpb.MetricDescriptor_HISTOGRAM_INT64, m.MetricDescriptor.Type)
+		assert.Equal(t, []*metricpb.Int64DataPoint(nil), m.Int64DataPoints)
+		assert.Equal(t, []*metricpb.DoubleDataPoint(nil), m.DoubleDataPoints)
+		assert.Equal(t, []*metricpb.SummaryDataPoint(nil), m.SummaryDataPoints)
+		assert.Equal(t, expected, m.HistogramDataPoints)
+	}
+}
+
+func TestHistogramFloat64DataPoints(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	h := histogramAgg.New([]core.Number{core.NewFloat64Number(10)})
+	assert.NoError(t, h.Update(context.Background(), core.NewFloat64Number(1), &desc))
+	assert.NoError(t, h.Update(context.Background(), core.NewFloat64Number(10), &desc))
+	h.Checkpoint(context.Background(), &desc)
+
+	expected := []*metricpb.HistogramDataPoint{
+		{
+			Sum:            11,
+			Count:          2,
+			ExplicitBounds: []float64{10},
+			BucketCounts:   []uint64{1, 1},
+		},
+	}
+	m, err := histogram(&desc, labels, h)
+	if assert.NoError(t, err) {
+		assert.Equal(t, metricpb.MetricDescriptor_HISTOGRAM_DOUBLE, m.MetricDescriptor.Type)
+		assert.Equal(t, expected, m.HistogramDataPoints)
+	}
+}
+
 func TestSumErrUnknownValueType(t *testing.T) {
 	desc := metric.NewDescriptor("", metric.MeasureKind, core.NumberKind(-1))
 	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
@@ -295,3 +346,59 @@ func TestSumErrUnknownValueType(t *testing.T) {
 		t.Errorf("expected ErrUnknownValueType, got %v", err)
 	}
 }
+
+func TestDistributionDefaultQuantiles(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	d := ddsketch.New()
+	for _, v := range []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10} {
+		assert.NoError(t, d.Update(context.Background(), core.NewFloat64Number(v), &desc))
+	}
+	d.Checkpoint(context.Background(), &desc)
+
+	m, err := distribution(&desc, labels, d, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, metricpb.MetricDescriptor_SUMMARY, m.MetricDescriptor.Type)
+		if assert.Len(t, m.SummaryDataPoints, 1) {
+			dp := m.SummaryDataPoints[0]
+			assert.Equal(t, uint64(10), dp.Count)
+			assert.Equal(t, float64(55), dp.Sum)
+			if assert.Len(t, dp.PercentileValues, len(defaultQuantiles)) {
+				for i, q := range defaultQuantiles {
+					assert.Equal(t, q*100, dp.PercentileValues[i].Percentile)
+				}
+			}
+		}
+	}
+}
+
+func TestDistributionConfiguredQuantiles(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	d := ddsketch.New()
+	for _, v := range []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10} {
+		assert.NoError(t, d.Update(context.Background(), core.NewFloat64Number(v), &desc))
+	}
+	d.Checkpoint(context.Background(), &desc)
+
+	cfg := NewSummaryConfig(WithQuantiles([]float64{0.5}))
+	m, err := distribution(&desc, labels, d, cfg)
+	if assert.NoError(t, err) {
+		if assert.Len(t, m.SummaryDataPoints, 1) {
+			dp := m.SummaryDataPoints[0]
+			if assert.Len(t, dp.PercentileValues, 1) {
+				assert.Equal(t, 50.0, dp.PercentileValues[0].Percentile)
+			}
+		}
+	}
+}
+
+func TestDistributionPropagatesErrors(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	d := ddsketch.New()
+	d.Checkpoint(context.Background(), &desc)
+
+	_, err := distribution(&desc, labels, d, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metricpb "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+
+	"go.opentelemetry.io/otel/api/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+)
+
+// StartTimeMode selects how a StartTimeTracker assigns the
+// StartTimeUnixNano of a series' first data point.
+type StartTimeMode int
+
+const (
+	// StartTimeFromFirstObservation sets a series' start time to the
+	// checkpoint time at which that series was first observed. This
+	// matches how the Prometheus receiver's start-time adjuster behaves
+	// when it has no other signal to go on.
+	StartTimeFromFirstObservation StartTimeMode = iota
+
+	// StartTimeFromProcessStart sets every series' start time to the
+	// time the StartTimeTracker itself was created, on the assumption
+	// that it is constructed at process start.
+	StartTimeFromProcessStart
+)
+
+// seriesKey identifies one exported series: an instrument Descriptor
+// together with its encoded label set.
+type seriesKey struct {
+	descriptor *metric.Descriptor
+	labels     string
+}
+
+// seriesState is the per-series bookkeeping a StartTimeTracker needs to
+// assign start times and detect counter resets.
+type seriesState struct {
+	startTime time.Time
+	lastValue float64
+}
+
+// StartTimeTracker remembers, per exported series, the timestamp at
+// which that series was first observed, and attaches it to every
+// subsequently exported data point as StartTimeUnixNano. It also detects
+// monotonic counter resets: when a newly checkpointed sum is smaller
+// than the last one exported for the same series, the series' start
+// time is reset to the current checkpoint time so downstream cumulative
+// readers treat it as a fresh series rather than seeing the value go
+// negative. Any decrease of an Int64 or Double data point's value is
+// treated as a reset; whether the instrument is monotonic is not checked.
+type StartTimeTracker struct {
+	mode         StartTimeMode
+	processStart time.Time
+
+	mu     sync.Mutex
+	series map[seriesKey]*seriesState
+}
+
+// NewStartTimeTracker returns a StartTimeTracker operating in mode. It
+// should be constructed once, at process start, and reused across every
+// collection cycle.
+func NewStartTimeTracker(mode StartTimeMode) *StartTimeTracker {
+	return &StartTimeTracker{
+		mode:         mode,
+		processStart: now(),
+		series:       map[seriesKey]*seriesState{},
+	}
+}
+
+// now is a var so tests can fix the "process start" timestamp.
+var now = time.Now
+
+// Record attaches StartTimeUnixNano to every Int64DataPoint,
+// DoubleDataPoint, and SummaryDataPoint in m, and rewrites the start
+// time (but not the exported value) of a series whose sum has gone
+// backward since the last call. checkpointTime is the time the
+// Aggregator producing m was checkpointed.
+func (t *StartTimeTracker) Record(desc *metric.Descriptor, labels export.Labels, m *metricpb.Metric, checkpointTime time.Time) {
+	key := seriesKey{descriptor: desc, labels: encodeLabels(labels)}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, seen := t.series[key]
+	if !seen {
+		s = &seriesState{}
+		t.series[key] = s
+	}
+	start := t.startTimeLocked(s, seen, checkpointTime)
+
+	for _, dp := range m.Int64DataPoints {
+		start = t.detectResetLocked(s, seen, float64(dp.Value), checkpointTime, start)
+		dp.StartTimeUnixNano = uint64(start.UnixNano())
+		s.lastValue = float64(dp.Value)
+		seen = true
+	}
+	for _, dp := range m.DoubleDataPoints {
+		start = t.detectResetLocked(s, seen, dp.Value, checkpointTime, start)
+		dp.StartTimeUnixNano = uint64(start.UnixNano())
+		s.lastValue = dp.Value
+		seen = true
+	}
+	for _, dp := range m.SummaryDataPoints {
+		dp.StartTimeUnixNano = uint64(start.UnixNano())
+	}
+}
+
+// startTimeLocked returns the start time to use for s, initializing it
+// on first observation according to t.mode. Callers must hold t.mu.
+func (t *StartTimeTracker) startTimeLocked(s *seriesState, seen bool, checkpointTime time.Time) time.Time {
+	if seen {
+		return s.startTime
+	}
+	switch t.mode {
+	case StartTimeFromProcessStart:
+		s.startTime = t.processStart
+	default: // StartTimeFromFirstObservation
+		s.startTime = checkpointTime
+	}
+	return s.startTime
+}
+
+// detectResetLocked returns start, or checkpointTime (recorded as s's
+// new start time) if value is a counter reset relative to the series'
+// last exported value. Callers must hold t.mu.
+func (t *StartTimeTracker) detectResetLocked(s *seriesState, seen bool, value float64, checkpointTime, start time.Time) time.Time {
+	if seen && value < s.lastValue {
+		s.startTime = checkpointTime
+		return checkpointTime
+	}
+	return start
+}
+
+// encodeLabels produces a canonical string key for labels, suitable for
+// use as a map key alongside a *metric.Descriptor. Each key and value is
+// length-prefixed so that two distinct label sets can never collide on
+// the same encoded string merely because one label's value happens to
+// contain the bytes used to separate entries.
+func encodeLabels(labels export.Labels) string {
+	var sb strings.Builder
+	iter := labels.Iter()
+	for iter.Next() {
+		kv := iter.Label()
+		key := string(kv.Key)
+		value := kv.Value.Emit()
+		sb.WriteString(strconv.Itoa(len(key)))
+		sb.WriteByte(':')
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Itoa(len(value)))
+		sb.WriteByte(':')
+		sb.WriteString(value)
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
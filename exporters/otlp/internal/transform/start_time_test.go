@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"testing"
+	"time"
+
+	metricpb "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+)
+
+func TestStartTimeFromFirstObservation(t *testing.T) {
+	desc := metric.NewDescriptor("counter", metric.CounterKind, core.Int64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	tracker := NewStartTimeTracker(StartTimeFromFirstObservation)
+
+	t1 := time.Unix(100, 0)
+	m1 := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 1}}}
+	tracker.Record(&desc, labels, m1, t1)
+	assert.Equal(t, uint64(t1.UnixNano()), m1.Int64DataPoints[0].StartTimeUnixNano)
+
+	// A later checkpoint of the same series keeps the original start time.
+	t2 := time.Unix(200, 0)
+	m2 := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 2}}}
+	tracker.Record(&desc, labels, m2, t2)
+	assert.Equal(t, uint64(t1.UnixNano()), m2.Int64DataPoints[0].StartTimeUnixNano)
+}
+
+func TestStartTimeFromProcessStart(t *testing.T) {
+	desc := metric.NewDescriptor("counter", metric.CounterKind, core.Int64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	tracker := NewStartTimeTracker(StartTimeFromProcessStart)
+
+	checkpointTime := time.Unix(500, 0)
+	m := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 1}}}
+	tracker.Record(&desc, labels, m, checkpointTime)
+
+	assert.Equal(t, uint64(tracker.processStart.UnixNano()), m.Int64DataPoints[0].StartTimeUnixNano)
+	assert.NotEqual(t, uint64(checkpointTime.UnixNano()), m.Int64DataPoints[0].StartTimeUnixNano)
+}
+
+func TestStartTimeResetDetection(t *testing.T) {
+	desc := metric.NewDescriptor("counter", metric.CounterKind, core.Int64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	tracker := NewStartTimeTracker(StartTimeFromFirstObservation)
+
+	t1 := time.Unix(100, 0)
+	m1 := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 10}}}
+	tracker.Record(&desc, labels, m1, t1)
+
+	t2 := time.Unix(200, 0)
+	m2 := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 20}}}
+	tracker.Record(&desc, labels, m2, t2)
+	assert.Equal(t, uint64(t1.UnixNano()), m2.Int64DataPoints[0].StartTimeUnixNano)
+
+	// Value drops: process restarted, or the sum was reset. The start
+	// time should move to the checkpoint at which the reset was
+	// detected, and the absolute (not re-based) value is kept.
+	t3 := time.Unix(300, 0)
+	m3 := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 5}}}
+	tracker.Record(&desc, labels, m3, t3)
+	assert.Equal(t, uint64(t3.UnixNano()), m3.Int64DataPoints[0].StartTimeUnixNano)
+	assert.Equal(t, int64(5), m3.Int64DataPoints[0].Value)
+
+	// A subsequent checkpoint keeps the new (post-reset) start time.
+	t4 := time.Unix(400, 0)
+	m4 := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 6}}}
+	tracker.Record(&desc, labels, m4, t4)
+	assert.Equal(t, uint64(t3.UnixNano()), m4.Int64DataPoints[0].StartTimeUnixNano)
+}
+
+func TestStartTimeDistinctSeries(t *testing.T) {
+	descA := metric.NewDescriptor("a", metric.CounterKind, core.Int64NumberKind)
+	descB := metric.NewDescriptor("b", metric.CounterKind, core.Int64NumberKind)
+	labels := export.NewSimpleLabels(export.NoopLabelEncoder{})
+	tracker := NewStartTimeTracker(StartTimeFromFirstObservation)
+
+	t1 := time.Unix(1, 0)
+	mA := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 1}}}
+	tracker.Record(&descA, labels, mA, t1)
+
+	t2 := time.Unix(2, 0)
+	mB := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 1}}}
+	tracker.Record(&descB, labels, mB, t2)
+
+	assert.Equal(t, uint64(t1.UnixNano()), mA.Int64DataPoints[0].StartTimeUnixNano)
+	assert.Equal(t, uint64(t2.UnixNano()), mB.Int64DataPoints[0].StartTimeUnixNano)
+}
+
+func TestEncodeLabelsNoCollision(t *testing.T) {
+	// These two label sets must not encode to the same string: the first
+	// has one label whose value contains the delimiter bytes used to
+	// join entries, the second has two labels that could otherwise be
+	// confused for it.
+	ambiguous := export.NewSimpleLabels(export.NoopLabelEncoder{}, key.String("a", "1,b=2"))
+	distinct := export.NewSimpleLabels(export.NoopLabelEncoder{}, key.String("a", "1"), key.String("b", "2"))
+
+	assert.NotEqual(t, encodeLabels(ambiguous), encodeLabels(distinct))
+}
+
+func TestStartTimeDistinctLabelsWithDelimiterLikeValues(t *testing.T) {
+	desc := metric.NewDescriptor("counter", metric.CounterKind, core.Int64NumberKind)
+	ambiguous := export.NewSimpleLabels(export.NoopLabelEncoder{}, key.String("a", "1,b=2"))
+	distinct := export.NewSimpleLabels(export.NoopLabelEncoder{}, key.String("a", "1"), key.String("b", "2"))
+	tracker := NewStartTimeTracker(StartTimeFromFirstObservation)
+
+	t1 := time.Unix(1, 0)
+	mA := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 1}}}
+	tracker.Record(&desc, ambiguous, mA, t1)
+
+	// A later checkpoint for the "distinct" label set must be treated as
+	// a brand-new series, not a continuation of "ambiguous"'s.
+	t2 := time.Unix(2, 0)
+	mB := &metricpb.Metric{Int64DataPoints: []*metricpb.Int64DataPoint{{Value: 1}}}
+	tracker.Record(&desc, distinct, mB, t2)
+
+	assert.Equal(t, uint64(t1.UnixNano()), mA.Int64DataPoints[0].StartTimeUnixNano)
+	assert.Equal(t, uint64(t2.UnixNano()), mB.Int64DataPoints[0].StartTimeUnixNano)
+}
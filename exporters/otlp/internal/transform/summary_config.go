@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+// defaultQuantiles are the percentiles reported for a Distribution or
+// ExponentialHistogram Aggregator when no Option configures otherwise.
+var defaultQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// SummaryConfig configures how SummaryDataPoints are produced from
+// Aggregators that support more than the fixed 0th/100th percentile
+// (min/max) pair, i.e. those implementing aggregator.Distribution or
+// aggregator.ExponentialHistogram.
+type SummaryConfig struct {
+	quantiles []float64
+}
+
+// Option applies a configuration choice to a SummaryConfig.
+type Option func(*SummaryConfig)
+
+// WithQuantiles sets the quantiles, as values in [0, 1], reported for
+// every Distribution and ExponentialHistogram Aggregator. The default is
+// {0.5, 0.9, 0.95, 0.99}.
+func WithQuantiles(quantiles []float64) Option {
+	return func(c *SummaryConfig) {
+		c.quantiles = quantiles
+	}
+}
+
+// NewSummaryConfig returns a SummaryConfig with opts applied.
+func NewSummaryConfig(opts ...Option) *SummaryConfig {
+	c := &SummaryConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// quantiles returns the configured quantiles, or defaultQuantiles if c
+// is nil or none were configured.
+func (c *SummaryConfig) quantiles() []float64 {
+	if c == nil || len(c.quantiles) == 0 {
+		return defaultQuantiles
+	}
+	return c.quantiles
+}
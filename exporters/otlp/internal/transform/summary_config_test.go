@@ -0,0 +1,32 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryConfigDefaultQuantiles(t *testing.T) {
+	var nilCfg *SummaryConfig
+	assert.Equal(t, defaultQuantiles, nilCfg.quantiles())
+	assert.Equal(t, defaultQuantiles, NewSummaryConfig().quantiles())
+}
+
+func TestSummaryConfigWithQuantiles(t *testing.T) {
+	cfg := NewSummaryConfig(WithQuantiles([]float64{0.1, 0.5, 0.9}))
+	assert.Equal(t, []float64{0.1, 0.5, 0.9}, cfg.quantiles())
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aggregator defines the interfaces the SDK uses to checkpoint and
+// export the state of instrument Aggregators. An Aggregator is identified by
+// the sub-interfaces it implements, e.g., Sum, MinMaxSumCount.
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+)
+
+// ErrNoData is returned when (due to a race with collection) the
+// Aggregator is check-pointed before the first value is set. The
+// aggregator should simply be skipped in this case.
+var ErrNoData = errors.New("no data collected by this aggregator")
+
+// Aggregator implements a specific aggregation behavior, e.g., a
+// behavior to track a sum, an LastValue, or a Distribution. The SDK
+// supports binding one `Aggregator` with each metric instrument and
+// label set.
+type Aggregator interface {
+	// Update receives a new measured value and incorporates it into
+	// the aggregation.
+	Update(ctx context.Context, number core.Number, descriptor *metric.Descriptor) error
+
+	// Checkpoint is called during collection to finish one period of
+	// aggregation by atomically saving the currently-updating state
+	// into the checkpoint.
+	Checkpoint(ctx context.Context, descriptor *metric.Descriptor)
+
+	// Merge combines the checkpointed state from two Aggregators into
+	// one, taking the sum of their respective checkpoints.
+	Merge(aggregator2 Aggregator, descriptor *metric.Descriptor) error
+}
+
+// NewInconsistentMergeError formats an error describing an attempt to
+// merge Aggregators of different types.
+func NewInconsistentMergeError(a1, a2 Aggregator) error {
+	return fmt.Errorf("cannot merge %T with %T: %w", a1, a2, ErrInconsistentType)
+}
+
+// ErrInconsistentType is returned when an Aggregator merges with
+// another Aggregator of a different type.
+var ErrInconsistentType = errors.New("inconsistent aggregator types")
+
+// Sum returns an aggregated sum.
+type Sum interface {
+	Sum() (core.Number, error)
+}
+
+// MinMaxSumCount returns the minimum, maximum, sum, and count of
+// measurement values that were aggregated.
+type MinMaxSumCount interface {
+	Sum
+	Min() (core.Number, error)
+	Max() (core.Number, error)
+	Count() (int64, error)
+}
+
+// Histogram returns the sum, count, and bucket counts of measurement
+// values that were aggregated, along with the boundaries that define
+// each bucket. Histogram implies Sum: a Histogram Aggregator always
+// also implements Sum.
+type Histogram interface {
+	Sum
+	Count() (int64, error)
+	Histogram() (buckets []core.Number, counts []uint64, err error)
+}
+
+// ExponentialHistogram returns the bucket layout of a base-2 exponential
+// (sparse) histogram: its current scale, the count of zero-valued
+// measurements, and the offset and dense bucket counts of its positive
+// and negative ranges. ExponentialHistogram implies Sum.
+type ExponentialHistogram interface {
+	Sum
+	Count() (int64, error)
+	Scale() int8
+	ZeroCount() uint64
+	Positive() (offset int, counts []uint64)
+	Negative() (offset int, counts []uint64)
+}
+
+// Distribution returns an arbitrary quantile of the measurement values
+// that were aggregated, in addition to their sum and count. Unlike
+// MinMaxSumCount, a Distribution Aggregator can answer for any quantile,
+// not just the minimum and maximum.
+type Distribution interface {
+	Sum
+	Count() (int64, error)
+	Quantile(q float64) (core.Number, error)
+}
@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ddsketch implements a simplified DDSketch Aggregator: a
+// quantile sketch with relative-error guarantees, using logarithmically
+// spaced buckets indexed by value rather than rank. See "DDSketch: A
+// Fast and Fully-Mergeable Quantile Sketch with Relative-Error
+// Guarantees" (Masson, Rim, Lee).
+package ddsketch
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregator"
+)
+
+// DefaultRelativeAccuracy is the relative error used when no Option
+// overrides it. A quantile answered by the sketch is guaranteed to be
+// within this fraction of the true value.
+const DefaultRelativeAccuracy = 0.01
+
+type state struct {
+	counts        map[int]uint64
+	zeroCount     uint64
+	negativeCount uint64
+	sum           core.Number
+	count         int64
+}
+
+func newState() state {
+	return state{counts: map[int]uint64{}}
+}
+
+// Aggregator is a DDSketch-based quantile Aggregator. It only supports
+// non-negative measurements (as is typical of latencies); negative
+// values are aggregated into the sum and count returned by Sum and
+// Count, but excluded from any bucket and from the ranking Quantile
+// computes.
+type Aggregator struct {
+	lock     sync.Mutex
+	alpha    float64
+	gamma    float64
+	logGamma float64
+
+	current    state
+	checkpoint state
+}
+
+var _ aggregator.Aggregator = &Aggregator{}
+var _ aggregator.Distribution = &Aggregator{}
+
+// Option configures an Aggregator returned by New.
+type Option func(*Aggregator)
+
+// WithRelativeAccuracy sets the sketch's relative accuracy. Smaller
+// values of alpha produce more buckets (more memory) in exchange for
+// tighter quantile estimates.
+func WithRelativeAccuracy(alpha float64) Option {
+	return func(a *Aggregator) {
+		a.alpha = alpha
+	}
+}
+
+// New returns a new DDSketch Aggregator.
+func New(opts ...Option) *Aggregator {
+	a := &Aggregator{alpha: DefaultRelativeAccuracy}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.gamma = (1 + a.alpha) / (1 - a.alpha)
+	a.logGamma = math.Log(a.gamma)
+	a.current = newState()
+	a.checkpoint = newState()
+	return a
+}
+
+// Sum returns the sum of all values aggregated, from the last checkpoint.
+func (c *Aggregator) Sum() (core.Number, error) {
+	return c.checkpoint.sum, nil
+}
+
+// Count returns the number of values aggregated, from the last checkpoint.
+func (c *Aggregator) Count() (int64, error) {
+	return c.checkpoint.count, nil
+}
+
+// Update adds a new measurement to the current state.
+func (c *Aggregator) Update(ctx context.Context, number core.Number, desc *metric.Descriptor) error {
+	kind := desc.NumberKind()
+	v := number.CoerceToFloat64(kind)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.current.count++
+	c.current.sum.AddNumber(kind, number)
+
+	if v < 0 {
+		c.current.negativeCount++
+		return nil
+	}
+	if v == 0 {
+		c.current.zeroCount++
+		return nil
+	}
+	idx := int(math.Ceil(math.Log(v) / c.logGamma))
+	c.current.counts[idx]++
+	return nil
+}
+
+// Checkpoint saves the current state and resets the current state to
+// empty, taking a lock to prevent concurrent Update() calls.
+func (c *Aggregator) Checkpoint(ctx context.Context, desc *metric.Descriptor) {
+	c.lock.Lock()
+	c.checkpoint, c.current = c.current, newState()
+	c.lock.Unlock()
+}
+
+// Merge combines two DDSketches built with the same relative accuracy.
+func (c *Aggregator) Merge(oa aggregator.Aggregator, desc *metric.Descriptor) error {
+	o, _ := oa.(*Aggregator)
+	if o == nil {
+		return aggregator.NewInconsistentMergeError(c, oa)
+	}
+
+	c.checkpoint.sum.AddNumber(desc.NumberKind(), o.checkpoint.sum)
+	c.checkpoint.count += o.checkpoint.count
+	c.checkpoint.zeroCount += o.checkpoint.zeroCount
+	c.checkpoint.negativeCount += o.checkpoint.negativeCount
+	for idx, cnt := range o.checkpoint.counts {
+		c.checkpoint.counts[idx] += cnt
+	}
+	return nil
+}
+
+// Quantile returns an estimate of the value at quantile q (in [0, 1]),
+// accurate to within the sketch's relative accuracy. Negative
+// measurements are excluded from the ranking, since they are not placed
+// into any bucket (see the Aggregator doc comment); the rank is computed
+// over the non-negative subpopulation alone. It returns
+// aggregator.ErrNoData if the sketch has not been checkpointed with any
+// non-negative data yet.
+func (c *Aggregator) Quantile(q float64) (core.Number, error) {
+	nonNegative := int64(c.checkpoint.zeroCount) + int64(sumCounts(c.checkpoint.counts))
+	if nonNegative == 0 {
+		return core.Number(0), aggregator.ErrNoData
+	}
+
+	target := q * float64(nonNegative-1)
+	var cumulative float64
+
+	if c.checkpoint.zeroCount > 0 {
+		if cumulative+float64(c.checkpoint.zeroCount) > target {
+			return core.NewFloat64Number(0), nil
+		}
+		cumulative += float64(c.checkpoint.zeroCount)
+	}
+
+	indices := make([]int, 0, len(c.checkpoint.counts))
+	for idx := range c.checkpoint.counts {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	for _, idx := range indices {
+		cnt := float64(c.checkpoint.counts[idx])
+		if cumulative+cnt > target {
+			return core.NewFloat64Number(c.bucketValue(idx)), nil
+		}
+		cumulative += cnt
+	}
+
+	if len(indices) > 0 {
+		return core.NewFloat64Number(c.bucketValue(indices[len(indices)-1])), nil
+	}
+	return core.NewFloat64Number(0), nil
+}
+
+// sumCounts returns the total number of measurements recorded across all
+// buckets.
+func sumCounts(counts map[int]uint64) uint64 {
+	var total uint64
+	for _, cnt := range counts {
+		total += cnt
+	}
+	return total
+}
+
+// bucketValue returns the DDSketch estimate for a value that mapped to
+// bucket idx: the harmonic mean of the bucket's (gamma**(idx-1), gamma**idx]
+// bounds, which lies within the relative accuracy of every value in it.
+func (c *Aggregator) bucketValue(idx int) float64 {
+	return 2 * math.Pow(c.gamma, float64(idx)) / (c.gamma + 1)
+}
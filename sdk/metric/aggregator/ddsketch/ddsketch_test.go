@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddsketch
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregator"
+)
+
+func update(t *testing.T, a *Aggregator, desc *metric.Descriptor, values ...float64) {
+	t.Helper()
+	for _, v := range values {
+		require.NoError(t, a.Update(context.Background(), core.NewFloat64Number(v), desc))
+	}
+}
+
+func TestDDSketchSumCount(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New()
+	update(t, a, &desc, 1, 2, 3, 4, -1, 0)
+	a.Checkpoint(context.Background(), &desc)
+
+	count, err := a.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), count)
+
+	sum, err := a.Sum()
+	require.NoError(t, err)
+	assert.Equal(t, core.NewFloat64Number(9), sum)
+}
+
+func TestDDSketchNoData(t *testing.T) {
+	a := New()
+	a.Checkpoint(context.Background(), &metric.Descriptor{})
+
+	_, err := a.Quantile(0.5)
+	assert.Equal(t, aggregator.ErrNoData, err)
+}
+
+func TestDDSketchQuantileAccuracy(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New(WithRelativeAccuracy(0.01))
+
+	rnd := rand.New(rand.NewSource(1))
+	values := make([]float64, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		values = append(values, 1+rnd.Float64()*99)
+	}
+	update(t, a, &desc, values...)
+	a.Checkpoint(context.Background(), &desc)
+
+	median, err := a.Quantile(0.5)
+	require.NoError(t, err)
+	got := median.CoerceToFloat64(core.Float64NumberKind)
+	// The true median of Uniform(1, 100) is ~50; allow slack beyond the
+	// sketch's relative accuracy for sampling noise.
+	assert.InDelta(t, 50, got, 10)
+}
+
+func TestDDSketchMerge(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New()
+	update(t, a, &desc, 1, 2, 3)
+	a.Checkpoint(context.Background(), &desc)
+
+	b := New()
+	update(t, b, &desc, 4, 5)
+	b.Checkpoint(context.Background(), &desc)
+
+	require.NoError(t, a.Merge(b, &desc))
+
+	count, err := a.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+
+	sum, err := a.Sum()
+	require.NoError(t, err)
+	assert.Equal(t, core.NewFloat64Number(15), sum)
+}
+
+func TestDDSketchNegativeValuesExcludedFromQuantile(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New()
+	// Negatives should count toward Sum/Count but not shift the
+	// quantile estimate of the non-negative values.
+	update(t, a, &desc, -5, -3, -1, 1, 2, 3, 4)
+	a.Checkpoint(context.Background(), &desc)
+
+	count, err := a.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+
+	median, err := a.Quantile(0.5)
+	require.NoError(t, err)
+	got := median.CoerceToFloat64(core.Float64NumberKind)
+	assert.InDelta(t, 2, got, 1)
+}
+
+func TestDDSketchAllNegativeNoData(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New()
+	update(t, a, &desc, -1, -2, -3)
+	a.Checkpoint(context.Background(), &desc)
+
+	_, err := a.Quantile(0.5)
+	assert.Equal(t, aggregator.ErrNoData, err)
+}
+
+func TestDDSketchZeroValues(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New()
+	update(t, a, &desc, 0, 0, 1)
+	a.Checkpoint(context.Background(), &desc)
+
+	median, err := a.Quantile(0.5)
+	require.NoError(t, err)
+	assert.Equal(t, core.NewFloat64Number(0), median)
+}
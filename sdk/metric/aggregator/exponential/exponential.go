@@ -0,0 +1,339 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exponential implements a base-2 exponential bucket histogram
+// Aggregator. Unlike the fixed-boundary histogram package, the bucket
+// boundaries are not configured up front: the Aggregator picks a scale
+// that keeps the number of buckets bounded by MaxSize, making it suitable
+// for latency distributions that span many orders of magnitude.
+package exponential
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregator"
+)
+
+const (
+	// DefaultMaxSize is the default number of buckets maintained per
+	// sign (positive and negative) before the Aggregator rescales.
+	DefaultMaxSize = 160
+
+	// MinScale and MaxScale bound the resolution of the histogram. At
+	// MinScale the Aggregator has a growth factor of 2**(2**10); at
+	// MaxScale, 2**(2**-20).
+	MinScale int8 = -10
+	MaxScale int8 = 20
+)
+
+// ErrMaxSizeTooSmall is returned by Update when a measurement would
+// require downscaling past MinScale to keep both the positive and
+// negative bucket counts within MaxSize. This happens when MaxSize is
+// configured too small to span the recorded values' range of magnitude
+// at any supported scale.
+var ErrMaxSizeTooSmall = errors.New("exponential histogram: MaxSize too small to represent the recorded range")
+
+// buckets is a contiguous, dense array of bucket counts. counts[i]
+// corresponds to bucket index offset+i.
+type buckets struct {
+	offset int
+	counts []uint64
+}
+
+type state struct {
+	scale     int8
+	zeroCount uint64
+	positive  buckets
+	negative  buckets
+	sum       core.Number
+	count     int64
+}
+
+// Aggregator aggregates measurements into a base-2 exponential histogram,
+// rescaling automatically to stay within MaxSize buckets.
+type Aggregator struct {
+	lock       sync.Mutex
+	maxSize    int
+	current    state
+	checkpoint state
+}
+
+var _ aggregator.Aggregator = &Aggregator{}
+var _ aggregator.Sum = &Aggregator{}
+
+// Option configures an Aggregator returned by New.
+type Option func(*Aggregator)
+
+// WithMaxSize sets the maximum number of buckets maintained per sign. The
+// Aggregator halves its resolution (and its bucket count) whenever this
+// limit is exceeded. The default is DefaultMaxSize. Setting n too small
+// to span the recorded values' range of magnitude at any scale down to
+// MinScale causes Update to return ErrMaxSizeTooSmall.
+func WithMaxSize(n int) Option {
+	return func(a *Aggregator) {
+		a.maxSize = n
+	}
+}
+
+// New returns a new exponential histogram Aggregator.
+func New(opts ...Option) *Aggregator {
+	a := &Aggregator{maxSize: DefaultMaxSize}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.current.scale = MaxScale
+	a.checkpoint.scale = MaxScale
+	return a
+}
+
+// Sum returns the sum of all values aggregated, from the last checkpoint.
+func (c *Aggregator) Sum() (core.Number, error) {
+	return c.checkpoint.sum, nil
+}
+
+// Count returns the number of values aggregated, from the last checkpoint.
+func (c *Aggregator) Count() (int64, error) {
+	return c.checkpoint.count, nil
+}
+
+// Scale returns the checkpointed histogram's current resolution.
+func (c *Aggregator) Scale() int8 {
+	return c.checkpoint.scale
+}
+
+// ZeroCount returns the number of zero-valued measurements, from the
+// last checkpoint.
+func (c *Aggregator) ZeroCount() uint64 {
+	return c.checkpoint.zeroCount
+}
+
+// Positive returns the offset of the first positive bucket and its
+// dense bucket counts, from the last checkpoint.
+func (c *Aggregator) Positive() (offset int, counts []uint64) {
+	return c.checkpoint.positive.offset, c.checkpoint.positive.counts
+}
+
+// Negative returns the offset of the first negative bucket (of |v|) and
+// its dense bucket counts, from the last checkpoint.
+func (c *Aggregator) Negative() (offset int, counts []uint64) {
+	return c.checkpoint.negative.offset, c.checkpoint.negative.counts
+}
+
+// Update adds a new measurement to the current state.
+func (c *Aggregator) Update(ctx context.Context, number core.Number, desc *metric.Descriptor) error {
+	kind := desc.NumberKind()
+	v := number.CoerceToFloat64(kind)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.current.count++
+	c.current.sum.AddNumber(kind, number)
+
+	switch {
+	case v == 0:
+		c.current.zeroCount++
+	case v > 0:
+		return c.updateBucket(&c.current.positive, v)
+	default:
+		return c.updateBucket(&c.current.negative, -v)
+	}
+	return nil
+}
+
+// updateBucket increments the bucket containing v, rescaling (halving
+// resolution) first if v's index would otherwise land outside the span
+// b can hold within maxSize buckets, then growing the dense array to
+// the bounded result. Rescaling before growing keeps the array from
+// ever being allocated at a finer, unbounded resolution: without it, a
+// single value many orders of magnitude away from those already
+// recorded could force a huge allocation at the current (possibly very
+// fine) scale, even though the final, downscaled result would easily
+// fit in maxSize buckets. It returns ErrMaxSizeTooSmall if maxSize is
+// too small to hold v's range at any supported scale.
+func (c *Aggregator) updateBucket(b *buckets, v float64) error {
+	for {
+		idx := index(v, c.current.scale)
+		lo, hi := idx, idx
+		if len(b.counts) > 0 {
+			if b.offset < lo {
+				lo = b.offset
+			}
+			if last := b.offset + len(b.counts) - 1; last > hi {
+				hi = last
+			}
+		}
+		if hi-lo < c.maxSize {
+			break
+		}
+		if err := c.downscale(); err != nil {
+			return err
+		}
+	}
+
+	idx := index(v, c.current.scale)
+	switch {
+	case len(b.counts) == 0:
+		b.offset = idx
+		b.counts = []uint64{0}
+	case idx < b.offset:
+		growFront(b, idx)
+	case idx >= b.offset+len(b.counts):
+		growBack(b, idx)
+	}
+	b.counts[idx-b.offset]++
+	return nil
+}
+
+// index returns the bucket index that v (v > 0) maps to at the given
+// scale: floor(log2(v) * 2**scale).
+func index(v float64, scale int8) int {
+	return int(math.Floor(math.Log2(v) * math.Ldexp(1, int(scale))))
+}
+
+// growFront extends b so that idx becomes its new first bucket.
+func growFront(b *buckets, idx int) {
+	grow := b.offset - idx
+	counts := make([]uint64, grow+len(b.counts))
+	copy(counts[grow:], b.counts)
+	b.counts = counts
+	b.offset = idx
+}
+
+// growBack extends b so that idx becomes a valid (last) bucket.
+func growBack(b *buckets, idx int) {
+	grow := idx - (b.offset + len(b.counts) - 1)
+	b.counts = append(b.counts, make([]uint64, grow)...)
+}
+
+// downscale halves the Aggregator's resolution by merging adjacent
+// bucket pairs in both the positive and negative ranges and
+// decrementing scale. It returns ErrMaxSizeTooSmall if scale is already
+// at MinScale, which should not happen given DefaultMaxSize leaves ample
+// headroom but is reachable with a small WithMaxSize.
+func (c *Aggregator) downscale() error {
+	if c.current.scale <= MinScale {
+		return ErrMaxSizeTooSmall
+	}
+	collapse(&c.current.positive)
+	collapse(&c.current.negative)
+	c.current.scale--
+	return nil
+}
+
+// collapse merges each adjacent pair of buckets in b, halving its
+// length (rounding up) and its offset.
+func collapse(b *buckets) {
+	if len(b.counts) == 0 {
+		return
+	}
+	if b.offset%2 != 0 {
+		b.counts = append([]uint64{0}, b.counts...)
+		b.offset--
+	}
+	merged := make([]uint64, (len(b.counts)+1)/2)
+	for i, cnt := range b.counts {
+		merged[i/2] += cnt
+	}
+	b.counts = merged
+	b.offset /= 2
+}
+
+// Checkpoint saves the current state and resets the current state to
+// empty, taking a lock to prevent concurrent Update() calls.
+func (c *Aggregator) Checkpoint(ctx context.Context, desc *metric.Descriptor) {
+	c.lock.Lock()
+	c.checkpoint, c.current = c.current, state{scale: MaxScale}
+	c.lock.Unlock()
+}
+
+// Merge combines two exponential histograms, downscaling to the coarser
+// of the two scales before summing bucket counts, and downscaling further
+// afterward while either range spans more than MaxSize buckets (stopping
+// at MinScale). oa's checkpoint is
+// read but never modified: any downscaling needed to bring oa to the
+// merged scale is performed on a copy of its buckets, so a caller that
+// reuses or re-merges oa afterward still sees oa's own data intact.
+func (c *Aggregator) Merge(oa aggregator.Aggregator, desc *metric.Descriptor) error {
+	o, _ := oa.(*Aggregator)
+	if o == nil {
+		return aggregator.NewInconsistentMergeError(c, oa)
+	}
+
+	target := c.checkpoint.scale
+	if o.checkpoint.scale < target {
+		target = o.checkpoint.scale
+	}
+
+	for c.checkpoint.scale > target {
+		collapse(&c.checkpoint.positive)
+		collapse(&c.checkpoint.negative)
+		c.checkpoint.scale--
+	}
+
+	oPositive, oNegative := o.checkpoint.positive, o.checkpoint.negative
+	if o.checkpoint.scale > target {
+		oPositive, oNegative = copyBuckets(oPositive), copyBuckets(oNegative)
+		for oScale := o.checkpoint.scale; oScale > target; oScale-- {
+			collapse(&oPositive)
+			collapse(&oNegative)
+		}
+	}
+
+	c.checkpoint.sum.AddNumber(desc.NumberKind(), o.checkpoint.sum)
+	c.checkpoint.count += o.checkpoint.count
+	c.checkpoint.zeroCount += o.checkpoint.zeroCount
+	mergeBuckets(&c.checkpoint.positive, &oPositive)
+	mergeBuckets(&c.checkpoint.negative, &oNegative)
+	for c.checkpoint.scale > MinScale &&
+		(len(c.checkpoint.positive.counts) > c.maxSize || len(c.checkpoint.negative.counts) > c.maxSize) {
+		collapse(&c.checkpoint.positive)
+		collapse(&c.checkpoint.negative)
+		c.checkpoint.scale--
+	}
+	return nil
+}
+
+// copyBuckets returns a deep copy of b, so the caller can freely
+// collapse the result without affecting b's owner.
+func copyBuckets(b buckets) buckets {
+	counts := make([]uint64, len(b.counts))
+	copy(counts, b.counts)
+	return buckets{offset: b.offset, counts: counts}
+}
+
+// mergeBuckets adds the counts of o into b, growing b as needed.
+func mergeBuckets(b, o *buckets) {
+	for i, cnt := range o.counts {
+		if cnt == 0 {
+			continue
+		}
+		idx := o.offset + i
+		switch {
+		case len(b.counts) == 0:
+			b.offset = idx
+			b.counts = []uint64{0}
+		case idx < b.offset:
+			growFront(b, idx)
+		case idx >= b.offset+len(b.counts):
+			growBack(b, idx)
+		}
+		b.counts[idx-b.offset] += cnt
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exponential
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+)
+
+func update(t *testing.T, a *Aggregator, desc *metric.Descriptor, values ...float64) {
+	t.Helper()
+	for _, v := range values {
+		require.NoError(t, a.Update(context.Background(), core.NewFloat64Number(v), desc))
+	}
+}
+
+func TestExponentialHistogramBasic(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New()
+	update(t, a, &desc, 1, 2, 4, -1, -2, 0)
+	a.Checkpoint(context.Background(), &desc)
+
+	count, err := a.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), count)
+
+	assert.Equal(t, uint64(1), a.ZeroCount())
+
+	_, posCounts := a.Positive()
+	var posTotal uint64
+	for _, c := range posCounts {
+		posTotal += c
+	}
+	assert.Equal(t, uint64(3), posTotal)
+
+	_, negCounts := a.Negative()
+	var negTotal uint64
+	for _, c := range negCounts {
+		negTotal += c
+	}
+	assert.Equal(t, uint64(2), negTotal)
+}
+
+func TestExponentialHistogramRescale(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New(WithMaxSize(4))
+
+	// Span far more than 4 buckets at full (MaxScale) resolution, forcing
+	// repeated downscale.
+	values := make([]float64, 0, 8)
+	for i := 0; i < 8; i++ {
+		values = append(values, math.Exp2(float64(i)))
+	}
+	update(t, a, &desc, values...)
+	a.Checkpoint(context.Background(), &desc)
+
+	assert.Less(t, a.Scale(), MaxScale)
+
+	_, posCounts := a.Positive()
+	assert.LessOrEqual(t, len(posCounts), 4)
+
+	count, err := a.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), count)
+}
+
+func TestExponentialHistogramBoundedGrowthAcrossWideRange(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New()
+
+	// 1e-6 and 1.0 are both ordinary latency values (microseconds and a
+	// full second), but span enough orders of magnitude at MaxScale that
+	// growing the bucket array before downscaling would allocate tens of
+	// millions of entries. Rescaling first should keep this call cheap.
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	update(t, a, &desc, 1e-6, 1.0)
+	runtime.ReadMemStats(&after)
+
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+
+	a.Checkpoint(context.Background(), &desc)
+	_, posCounts := a.Positive()
+	assert.LessOrEqual(t, len(posCounts), DefaultMaxSize)
+
+	count, err := a.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestExponentialHistogramMerge(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New()
+	update(t, a, &desc, 1, 2, 3)
+	a.Checkpoint(context.Background(), &desc)
+
+	b := New()
+	update(t, b, &desc, 4, 5)
+	b.Checkpoint(context.Background(), &desc)
+
+	require.NoError(t, a.Merge(b, &desc))
+
+	count, err := a.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+
+	sum, err := a.Sum()
+	require.NoError(t, err)
+	assert.Equal(t, core.NewFloat64Number(15), sum)
+}
+
+func TestExponentialHistogramMergeDoesNotMutateArgument(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+
+	// a has the coarser scale: a wide range forced into few buckets.
+	a := New(WithMaxSize(4))
+	update(t, a, &desc, 1, 2, 4, 8, 16, 32, 64, 128)
+	a.Checkpoint(context.Background(), &desc)
+	require.Less(t, a.Scale(), MaxScale)
+
+	// b keeps a finer scale than a: merging into a must downscale a copy
+	// of b's buckets, not b's own checkpoint.
+	b := New()
+	update(t, b, &desc, 1, 2, 3)
+	b.Checkpoint(context.Background(), &desc)
+	bScaleBefore := b.Scale()
+	_, bPosCountsBefore := b.Positive()
+	bPosCopyBefore := append([]uint64(nil), bPosCountsBefore...)
+
+	require.NoError(t, a.Merge(b, &desc))
+
+	// b's own checkpoint must be untouched by having been merged into a,
+	// even though a's coarser scale forced a downscaled copy of b's
+	// buckets to be used for the merge.
+	assert.Equal(t, bScaleBefore, b.Scale())
+	_, bPosCountsAfter := b.Positive()
+	assert.Equal(t, bPosCopyBefore, bPosCountsAfter)
+
+	bCount, err := b.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), bCount)
+}
+
+func TestExponentialDownscaleAtMinScaleReturnsError(t *testing.T) {
+	// White-box: force the Aggregator to the coarsest supported scale so
+	// downscale has nowhere left to go, the way Update would if MaxSize
+	// were configured too small to hold a measurement's range at any
+	// scale down to MinScale.
+	a := New()
+	a.current.scale = MinScale
+
+	err := a.downscale()
+	assert.Equal(t, ErrMaxSizeTooSmall, err)
+}
+
+func TestExponentialUpdatePropagatesMaxSizeTooSmall(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New(WithMaxSize(2))
+	// Start one step above MinScale: wide enough a spread between the
+	// smallest and largest representable float64 values still spans
+	// more than maxSize buckets, but only one downscale remains before
+	// Update must report failure instead of panicking.
+	a.current.scale = MinScale + 1
+
+	require.NoError(t, a.Update(context.Background(), core.NewFloat64Number(math.SmallestNonzeroFloat64), &desc))
+	err := a.Update(context.Background(), core.NewFloat64Number(math.MaxFloat64), &desc)
+	assert.Equal(t, ErrMaxSizeTooSmall, err)
+}
+
+func TestExponentialHistogramBucketsConserveCount(t *testing.T) {
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Float64NumberKind)
+	a := New(WithMaxSize(4))
+
+	rnd := rand.New(rand.NewSource(1))
+	values := make([]float64, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		values = append(values, 1+rnd.Float64()*99)
+	}
+	update(t, a, &desc, values...)
+	a.Checkpoint(context.Background(), &desc)
+
+	// Repeated downscaling must merge buckets, not drop measurements:
+	// the dense bucket counts (plus zeroCount) should still sum to the
+	// total recorded count.
+	_, posCounts := a.Positive()
+	var total uint64
+	for _, c := range posCounts {
+		total += c
+	}
+	total += a.ZeroCount()
+	assert.Equal(t, uint64(1000), total)
+}
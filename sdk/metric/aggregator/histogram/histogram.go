@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package histogram implements a fixed-bucket-boundary histogram
+// Aggregator.
+package histogram
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregator"
+)
+
+// Aggregator observes events and counts them in pre-determined buckets.
+// It also calculates the sum and count of all events.
+type Aggregator struct {
+	lock       sync.Mutex
+	current    state
+	checkpoint state
+	boundaries []core.Number
+}
+
+type state struct {
+	bucketCounts []uint64
+	sum          core.Number
+	count        int64
+}
+
+var _ aggregator.Aggregator = &Aggregator{}
+var _ aggregator.Histogram = &Aggregator{}
+
+// New returns a new histogram Aggregator that computes buckets from the
+// given boundaries. The boundaries are expected to be sorted in ascending
+// order; len(boundaries)+1 buckets are maintained.
+func New(boundaries []core.Number) *Aggregator {
+	return &Aggregator{
+		boundaries: boundaries,
+		current:    emptyState(len(boundaries)),
+		checkpoint: emptyState(len(boundaries)),
+	}
+}
+
+func emptyState(numBoundaries int) state {
+	return state{bucketCounts: make([]uint64, numBoundaries+1)}
+}
+
+// Sum returns the sum of values observed, from the last checkpoint.
+func (c *Aggregator) Sum() (core.Number, error) {
+	return c.checkpoint.sum, nil
+}
+
+// Count returns the number of values observed, from the last checkpoint.
+func (c *Aggregator) Count() (int64, error) {
+	return c.checkpoint.count, nil
+}
+
+// Histogram returns the bucket boundaries and the count observed in each
+// bucket, from the last checkpoint.
+func (c *Aggregator) Histogram() ([]core.Number, []uint64, error) {
+	return c.boundaries, c.checkpoint.bucketCounts, nil
+}
+
+// Checkpoint saves the current state and resets the current state to
+// the empty set, taking a lock to prevent concurrent Update() calls.
+func (c *Aggregator) Checkpoint(ctx context.Context, desc *metric.Descriptor) {
+	c.lock.Lock()
+	c.checkpoint, c.current = c.current, emptyState(len(c.boundaries))
+	c.lock.Unlock()
+}
+
+// Update adds the recorded measurement to the current state, incrementing
+// the bucket that the value falls within.
+func (c *Aggregator) Update(ctx context.Context, number core.Number, desc *metric.Descriptor) error {
+	bucketID := len(c.boundaries)
+	kind := desc.NumberKind()
+	for i, boundary := range c.boundaries {
+		if number.CompareNumber(kind, boundary) < 0 {
+			bucketID = i
+			break
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.current.count++
+	c.current.sum.AddNumber(kind, number)
+	c.current.bucketCounts[bucketID]++
+	return nil
+}
+
+// Merge combines two histograms that have the same boundaries into a
+// single one.
+func (c *Aggregator) Merge(oa aggregator.Aggregator, desc *metric.Descriptor) error {
+	o, _ := oa.(*Aggregator)
+	if o == nil {
+		return aggregator.NewInconsistentMergeError(c, oa)
+	}
+
+	c.checkpoint.sum.AddNumber(desc.NumberKind(), o.checkpoint.sum)
+	c.checkpoint.count += o.checkpoint.count
+
+	for i := 0; i < len(c.checkpoint.bucketCounts); i++ {
+		c.checkpoint.bucketCounts[i] += o.checkpoint.bucketCounts[i]
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+)
+
+func boundaries(values ...int64) []core.Number {
+	out := make([]core.Number, len(values))
+	for i, v := range values {
+		out[i] = core.NewInt64Number(v)
+	}
+	return out
+}
+
+func TestHistogramInt64(t *testing.T) {
+	ctx := context.Background()
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Int64NumberKind)
+	agg := New(boundaries(10, 20))
+
+	for _, v := range []int64{1, 10, 15, 25, 30} {
+		assert.NoError(t, agg.Update(ctx, core.NewInt64Number(v), &desc))
+	}
+	agg.Checkpoint(ctx, &desc)
+
+	sum, err := agg.Sum()
+	assert.NoError(t, err)
+	assert.Equal(t, core.NewInt64Number(81), sum)
+
+	count, err := agg.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+
+	bounds, counts, err := agg.Histogram()
+	assert.NoError(t, err)
+	assert.Equal(t, boundaries(10, 20), bounds)
+	assert.Equal(t, []uint64{1, 2, 2}, counts)
+}
+
+func TestHistogramMerge(t *testing.T) {
+	ctx := context.Background()
+	desc := metric.NewDescriptor("", metric.MeasureKind, core.Int64NumberKind)
+
+	a := New(boundaries(10, 20))
+	assert.NoError(t, a.Update(ctx, core.NewInt64Number(1), &desc))
+	a.Checkpoint(ctx, &desc)
+
+	b := New(boundaries(10, 20))
+	assert.NoError(t, b.Update(ctx, core.NewInt64Number(25), &desc))
+	b.Checkpoint(ctx, &desc)
+
+	assert.NoError(t, a.Merge(b, &desc))
+
+	count, err := a.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, counts, err := a.Histogram()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 0, 1}, counts)
+}